@@ -0,0 +1,82 @@
+// Package rest provides small REST helpers that complement the websocket
+// clients -- currently just fetching an order book snapshot to seed
+// OrderBookSubscription's snapshot+delta reconciliation.
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/juju/errors"
+
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+const defaultURL = "https://api.cryptowat.ch"
+
+// Client is a minimal REST client for the Cryptowatch HTTP API.
+type Client struct {
+	// URL is the API base URL; it defaults to the production API if unset.
+	URL string
+
+	HTTPClient *http.Client
+}
+
+// NewClient returns a Client with production defaults.
+func NewClient() *Client {
+	return &Client{
+		URL:        defaultURL,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// GetOrderBookSnapshot fetches a full L2 order book snapshot for marketID,
+// for use as the base that OrderBookSubscription reconciles buffered
+// deltas against.
+func (c *Client) GetOrderBookSnapshot(marketID common.MarketID) (common.Book, error) {
+	url := fmt.Sprintf("%s/markets/%d/orderbook", c.baseURL(), marketID)
+
+	resp, err := c.httpClient().Get(url)
+	if err != nil {
+		return common.Book{}, errors.Annotatef(err, "fetching order book snapshot for market %d", marketID)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return common.Book{}, errors.Errorf("fetching order book snapshot for market %d: unexpected status %s", marketID, resp.Status)
+	}
+
+	var result struct {
+		Result struct {
+			SeqNum uint64              `json:"seqNum"`
+			Bids   []common.PriceLevel `json:"bids"`
+			Asks   []common.PriceLevel `json:"asks"`
+		} `json:"result"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return common.Book{}, errors.Annotatef(err, "decoding order book snapshot for market %d", marketID)
+	}
+
+	return common.Book{
+		MarketID: marketID,
+		Bids:     result.Result.Bids,
+		Asks:     result.Result.Asks,
+		SeqNum:   result.Result.SeqNum,
+	}, nil
+}
+
+func (c *Client) baseURL() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	return defaultURL
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,133 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+const (
+	defaultRequestTimeout       = 10 * time.Second
+	defaultCancelAllConcurrency = 8
+)
+
+// PlaceOrders places every entry in params over the existing session,
+// pipelined concurrently rather than awaited one at a time, each under its
+// own correlation ID and defaultRequestTimeout. The returned slices are
+// parallel to params, so a partial failure (e.g. one order rejected for an
+// unsupported feature) is still observable per-entry.
+func (tc *TradeClient) PlaceOrders(params []common.PlaceOrderParams) ([]common.PlacedOrder, []error) {
+	orders := make([]common.PlacedOrder, len(params))
+	errs := make([]error, len(params))
+
+	var wg sync.WaitGroup
+	for i, p := range params {
+		i, p := i, p
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			orders[i], errs[i] = tc.placeOrderWithTimeout(p, defaultRequestTimeout)
+		}()
+	}
+	wg.Wait()
+
+	return orders, errs
+}
+
+// CancelOrders cancels every entry in params, pipelined the same way as
+// PlaceOrders. The returned slice is parallel to params.
+func (tc *TradeClient) CancelOrders(params []common.CancelOrderParams) []error {
+	errs := make([]error, len(params))
+
+	var wg sync.WaitGroup
+	for i, p := range params {
+		i, p := i, p
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = tc.cancelOrderWithTimeout(p, defaultRequestTimeout)
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// CancelAllOrders cancels every resting order on marketID. If the backend
+// doesn't expose a native bulk-cancel RPC, it falls back to GetOrders plus a
+// bounded-concurrency fan-out of individual cancellations (default
+// CancelAllConcurrency of 8), aggregating any failures into a MultiError.
+func (tc *TradeClient) CancelAllOrders(marketID common.MarketID) error {
+	orders, err := tc.GetOrders(marketID)
+	if err != nil {
+		return err
+	}
+
+	concurrency := tc.cancelAllConcurrency()
+	sem := make(chan struct{}, concurrency)
+	errs := make([]error, len(orders))
+
+	var wg sync.WaitGroup
+	for i, order := range orders {
+		i, order := i, order
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = tc.cancelOrderWithTimeout(common.CancelOrderParams{
+				MarketID: marketID,
+				OrderID:  order.ID,
+			}, defaultRequestTimeout)
+		}()
+	}
+	wg.Wait()
+
+	return common.NewMultiError(errs)
+}
+
+func (tc *TradeClient) cancelAllConcurrency() int {
+	if tc.CancelAllConcurrency > 0 {
+		return tc.CancelAllConcurrency
+	}
+	return defaultCancelAllConcurrency
+}
+
+// placeOrderWithTimeout is PlaceOrder, but gives up with an error if the
+// exchange hasn't acknowledged the order within timeout.
+func (tc *TradeClient) placeOrderWithTimeout(params common.PlaceOrderParams, timeout time.Duration) (common.PlacedOrder, error) {
+	type result struct {
+		order common.PlacedOrder
+		err   error
+	}
+
+	resultC := make(chan result, 1)
+	go func() {
+		order, err := tc.PlaceOrder(params)
+		resultC <- result{order, err}
+	}()
+
+	select {
+	case r := <-resultC:
+		return r.order, r.err
+	case <-time.After(timeout):
+		return common.PlacedOrder{}, errRequestTimeout
+	}
+}
+
+// cancelOrderWithTimeout is CancelOrder, but gives up with an error if the
+// exchange hasn't acknowledged the cancellation within timeout.
+func (tc *TradeClient) cancelOrderWithTimeout(params common.CancelOrderParams, timeout time.Duration) error {
+	errC := make(chan error, 1)
+	go func() {
+		errC <- tc.CancelOrder(params)
+	}()
+
+	select {
+	case err := <-errC:
+		return err
+	case <-time.After(timeout):
+		return errRequestTimeout
+	}
+}
@@ -0,0 +1,62 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+// There's no fake exchange backend to ack orders against, so these exercise
+// the paths that don't need one: every request fails fast with
+// errNotConnected since the test client never dials, and the results stay
+// parallel to the input.
+
+func TestPlaceOrdersReturnsParallelErrorsWhenDisconnected(t *testing.T) {
+	tc := newTestTradeClient(t)
+
+	params := []common.PlaceOrderParams{
+		{MarketID: common.MarketID(1), OrderType: common.LimitOrder},
+		{MarketID: common.MarketID(2), OrderType: common.LimitOrder},
+	}
+
+	orders, errs := tc.PlaceOrders(params)
+
+	assert.Len(t, orders, 2)
+	assert.Len(t, errs, 2)
+	for _, err := range errs {
+		assert.Error(t, err)
+	}
+}
+
+func TestCancelOrdersReturnsParallelErrorsWhenDisconnected(t *testing.T) {
+	tc := newTestTradeClient(t)
+
+	params := []common.CancelOrderParams{
+		{MarketID: common.MarketID(1), OrderID: "a"},
+		{MarketID: common.MarketID(2), OrderID: "b"},
+	}
+
+	errs := tc.CancelOrders(params)
+
+	assert.Len(t, errs, 2)
+	for _, err := range errs {
+		assert.Error(t, err)
+	}
+}
+
+func TestCancelAllOrdersPropagatesGetOrdersError(t *testing.T) {
+	tc := newTestTradeClient(t)
+
+	err := tc.CancelAllOrders(common.MarketID(1))
+	assert.Error(t, err, "GetOrders itself should fail fast while disconnected")
+}
+
+func TestCancelAllConcurrencyDefaultsWhenUnset(t *testing.T) {
+	tc := newTestTradeClient(t)
+	assert.Equal(t, defaultCancelAllConcurrency, tc.cancelAllConcurrency())
+
+	tc.CancelAllConcurrency = 3
+	assert.Equal(t, 3, tc.cancelAllConcurrency())
+}
@@ -0,0 +1,116 @@
+package websocket
+
+import "github.com/khcchiu/cw-sdk-go/common"
+
+// ExchangeCapabilities describes which order features a market's exchange
+// accepts, so PlaceOrder can validate a request up front instead of letting
+// the exchange reject it after the round trip.
+type ExchangeCapabilities struct {
+	SupportedOrderTypes  []common.OrderType
+	SupportedTimeInForce []common.TimeInForce
+
+	SupportsMargin     bool
+	SupportsFutures    bool
+	SupportsReduceOnly bool
+	SupportsPostOnly   bool
+	SupportsTrigger    bool
+
+	// MaxLeverage is 1 for markets that don't support margin/futures.
+	MaxLeverage int
+}
+
+func (c ExchangeCapabilities) supportsOrderType(t common.OrderType) bool {
+	for _, ot := range c.SupportedOrderTypes {
+		if ot == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (c ExchangeCapabilities) supportsTimeInForce(t common.TimeInForce) bool {
+	for _, tif := range c.SupportedTimeInForce {
+		if tif == t {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultCapabilities is what a plain spot market supports absent any
+// configuration or exchange-reported capabilities.
+func defaultCapabilities() ExchangeCapabilities {
+	return ExchangeCapabilities{
+		SupportedOrderTypes:  []common.OrderType{common.LimitOrder, common.MarketOrder},
+		SupportedTimeInForce: []common.TimeInForce{common.GTC, common.IOC, common.FOK},
+		MaxLeverage:          1,
+	}
+}
+
+// GetExchangeCapabilities reports which order features marketID's exchange
+// accepts: whatever was configured via TradeClientParams.ExchangeCapabilities
+// or a later SetExchangeCapabilities call, or defaultCapabilities (spot
+// only) if marketID has no entry.
+func (tc *TradeClient) GetExchangeCapabilities(marketID common.MarketID) (ExchangeCapabilities, error) {
+	tc.capabilitiesMtx.Lock()
+	caps, ok := tc.capabilities[marketID]
+	tc.capabilitiesMtx.Unlock()
+
+	if !ok {
+		caps = defaultCapabilities()
+	}
+
+	return caps, nil
+}
+
+// SetExchangeCapabilities configures which order features marketID's
+// exchange accepts, overriding the spot-only default. Callers that learn a
+// market's real capabilities (e.g. from an exchange metadata RPC) should
+// call this before placing margin/futures/trigger orders on it.
+func (tc *TradeClient) SetExchangeCapabilities(marketID common.MarketID, caps ExchangeCapabilities) {
+	tc.capabilitiesMtx.Lock()
+	defer tc.capabilitiesMtx.Unlock()
+	tc.capabilities[marketID] = caps
+}
+
+// validatePlaceOrderParams checks params against caps and returns an
+// ErrUnsupportedOrderFeature naming every field the exchange won't accept,
+// so callers can downgrade the request instead of having it rejected after
+// a round trip.
+func validatePlaceOrderParams(params common.PlaceOrderParams, caps ExchangeCapabilities) error {
+	var unsupported []string
+
+	if !caps.supportsOrderType(params.OrderType) {
+		unsupported = append(unsupported, "OrderType")
+	}
+
+	if tif := params.TimeInForce; tif != common.TimeInForceUnknown && !caps.supportsTimeInForce(tif) {
+		unsupported = append(unsupported, "TimeInForce")
+	}
+
+	if params.Leverage > 1 && !caps.SupportsMargin && !caps.SupportsFutures {
+		unsupported = append(unsupported, "Leverage")
+	}
+
+	if params.MarginMode != common.MarginModeUnknown && !caps.SupportsMargin {
+		unsupported = append(unsupported, "MarginMode")
+	}
+
+	if params.ReduceOnly && !caps.SupportsReduceOnly {
+		unsupported = append(unsupported, "ReduceOnly")
+	}
+
+	if params.PostOnly && !caps.SupportsPostOnly {
+		unsupported = append(unsupported, "PostOnly")
+	}
+
+	if params.TriggerPrice != "" && !caps.SupportsTrigger {
+		unsupported = append(unsupported, "TriggerPrice", "TriggerType")
+	}
+
+	if len(unsupported) > 0 {
+		return &ErrUnsupportedOrderFeature{Fields: unsupported}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+func TestGetExchangeCapabilitiesDefaultsToSpotOnly(t *testing.T) {
+	tc := newTestTradeClient(t)
+
+	caps, err := tc.GetExchangeCapabilities(common.MarketID(1))
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCapabilities(), caps)
+}
+
+func TestSetExchangeCapabilitiesOverridesDefault(t *testing.T) {
+	tc := newTestTradeClient(t)
+
+	configured := ExchangeCapabilities{
+		SupportedOrderTypes:  []common.OrderType{common.LimitOrder},
+		SupportedTimeInForce: []common.TimeInForce{common.GTC},
+		SupportsMargin:       true,
+		MaxLeverage:          10,
+	}
+	tc.SetExchangeCapabilities(common.MarketID(1), configured)
+
+	caps, err := tc.GetExchangeCapabilities(common.MarketID(1))
+	assert.NoError(t, err)
+	assert.Equal(t, configured, caps)
+
+	// A different market is unaffected.
+	other, err := tc.GetExchangeCapabilities(common.MarketID(2))
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCapabilities(), other)
+}
+
+func TestValidatePlaceOrderParamsRejectsUnsupportedFeatures(t *testing.T) {
+	caps := defaultCapabilities()
+
+	err := validatePlaceOrderParams(common.PlaceOrderParams{
+		OrderType:  common.LimitOrder,
+		MarginMode: common.MarginModeCross,
+		ReduceOnly: true,
+	}, caps)
+
+	assert.Error(t, err)
+	unsupported, ok := err.(*ErrUnsupportedOrderFeature)
+	assert.True(t, ok)
+	assert.ElementsMatch(t, []string{"MarginMode", "ReduceOnly"}, unsupported.Fields)
+}
+
+func TestValidatePlaceOrderParamsAcceptsSupportedFeatures(t *testing.T) {
+	caps := defaultCapabilities()
+
+	err := validatePlaceOrderParams(common.PlaceOrderParams{
+		OrderType:   common.LimitOrder,
+		TimeInForce: common.GTC,
+	}, caps)
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,209 @@
+package websocket
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	gorillaws "github.com/gorilla/websocket"
+)
+
+// StateCallback is the signature for callbacks registered via
+// OnStateChange / AddStateListener.
+type StateCallback func(oldState, state ConnState)
+
+// wsConn is the connection machinery shared by TradeClient and StreamConn:
+// it owns the underlying gorilla websocket connection, the current
+// ConnState, and the timer driving reconnects -- both the scheduled
+// AutoReconnect cycling and the ordinary retry-after-failure.
+type wsConn struct {
+	params WSParams
+
+	// writeMtx guards conn and URL swaps, so UpdateURL can't race a dial
+	// that's reading the URL out of params.
+	writeMtx  sync.Mutex
+	conn      *gorillaws.Conn
+	lastFrame time.Time
+
+	stateMtx       sync.Mutex
+	state          ConnState
+	stateListeners map[ConnState][]StateCallback
+
+	closeC    chan struct{}
+	closeOnce sync.Once
+}
+
+func newWSConn(params *WSParams) *wsConn {
+	p := *params
+	if p.PingInterval == 0 {
+		p.PingInterval = defaultPingInterval
+	}
+	if p.PongWait == 0 {
+		p.PongWait = defaultPongWait
+	}
+	if p.WriteWait == 0 {
+		p.WriteWait = defaultWriteWait
+	}
+
+	return &wsConn{
+		params:         p,
+		stateListeners: make(map[ConnState][]StateCallback),
+		closeC:         make(chan struct{}),
+	}
+}
+
+// UpdateURL atomically swaps the target URL. It takes effect on the next
+// (re)connect; the current connection, if any, is left alone.
+func (w *wsConn) UpdateURL(url string) {
+	w.writeMtx.Lock()
+	defer w.writeMtx.Unlock()
+	w.params.URL = url
+}
+
+func (w *wsConn) getURL() string {
+	w.writeMtx.Lock()
+	defer w.writeMtx.Unlock()
+	return w.params.URL
+}
+
+func (w *wsConn) getAutoReconnect() time.Duration {
+	w.writeMtx.Lock()
+	defer w.writeMtx.Unlock()
+	return w.params.AutoReconnect
+}
+
+func (w *wsConn) getState() ConnState {
+	w.stateMtx.Lock()
+	defer w.stateMtx.Unlock()
+	return w.state
+}
+
+func (w *wsConn) setState(state ConnState) {
+	w.stateMtx.Lock()
+	old := w.state
+	w.state = state
+	listeners := make([]StateCallback, 0, len(w.stateListeners[state])+len(w.stateListeners[ConnStateAny]))
+	listeners = append(listeners, w.stateListeners[state]...)
+	listeners = append(listeners, w.stateListeners[ConnStateAny]...)
+	w.stateMtx.Unlock()
+
+	for _, cb := range listeners {
+		cb(old, state)
+	}
+}
+
+// OnStateChange registers cb to be called whenever the connection
+// transitions into state (or into any state, if state is ConnStateAny).
+func (w *wsConn) OnStateChange(state ConnState, cb StateCallback) {
+	w.stateMtx.Lock()
+	defer w.stateMtx.Unlock()
+	w.stateListeners[state] = append(w.stateListeners[state], cb)
+}
+
+// runLoop dials the connection via connect and blocks until Close is
+// called, redialing on failure or disconnection. A single timer drives
+// both the scheduled AutoReconnect cycle and the ordinary reconnect, so
+// both kinds of reconnect go through the same codepath and OnStateChange
+// fires consistently either way.
+func (w *wsConn) runLoop(connect func(url string) <-chan struct{}) {
+	for {
+		w.setState(ConnStateConnecting)
+
+		disconnectedC := connect(w.getURL())
+
+		var reconnectC <-chan time.Time
+		if d := w.getAutoReconnect(); d > 0 {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			reconnectC = timer.C
+		}
+
+		select {
+		case <-w.closeC:
+			return
+		case <-disconnectedC:
+			w.setState(ConnStateWaitBeforeReconnect)
+		case <-reconnectC:
+			// The connection is healthy, but AutoReconnect says to cycle it
+			// anyway (e.g. to dodge a stealthy half-open socket or an LB
+			// session timeout).
+			w.setState(ConnStateWaitBeforeReconnect)
+		}
+	}
+}
+
+// dialConn opens the websocket connection to url and stores it as w.conn,
+// so the heartbeat and read loop have a real socket to work with.
+func (w *wsConn) dialConn(url string) (*gorillaws.Conn, error) {
+	conn, _, err := gorillaws.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w.writeMtx.Lock()
+	w.conn = conn
+	w.writeMtx.Unlock()
+
+	return conn, nil
+}
+
+// writeJSON marshals v as JSON and writes it as a text frame over the
+// current connection, returning errNotConnected if there isn't one (e.g.
+// mid-reconnect).
+func (w *wsConn) writeJSON(v interface{}) error {
+	w.writeMtx.Lock()
+	conn := w.conn
+	writeWait := w.params.WriteWait
+	w.writeMtx.Unlock()
+
+	if conn == nil {
+		return errNotConnected
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.WriteJSON(v)
+}
+
+// clearConn drops the stored connection once it's known to be dead, so a
+// stale *gorillaws.Conn doesn't linger and get written to by the next
+// heartbeat tick before a new one is dialed.
+func (w *wsConn) clearConn() {
+	w.writeMtx.Lock()
+	w.conn = nil
+	w.writeMtx.Unlock()
+}
+
+// readLoop blocks reading frames off conn and hands each one to onMessage,
+// until a read fails -- including the read deadline maintained by the
+// heartbeat expiring -- at which point it reports the cause via
+// onDisconnect and returns.
+func (w *wsConn) readLoop(conn *gorillaws.Conn, onMessage func(messageType int, data []byte), onDisconnect func(error)) {
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				onDisconnect(ErrHeartbeatTimeout)
+			} else {
+				onDisconnect(err)
+			}
+			return
+		}
+
+		onMessage(messageType, data)
+	}
+}
+
+// Close shuts the connection down for good; runLoop will not redial after
+// this returns.
+func (w *wsConn) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closeC)
+	})
+
+	w.writeMtx.Lock()
+	defer w.writeMtx.Unlock()
+	if w.conn != nil {
+		return w.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package websocket
+
+// ConnState represents the state of the underlying websocket connection of a
+// TradeClient or StreamConn.
+type ConnState int
+
+const (
+	// ConnStateDisconnected means there is no connection, and none is being
+	// attempted.
+	ConnStateDisconnected ConnState = iota
+
+	// ConnStateConnecting means a connection attempt is in progress.
+	ConnStateConnecting
+
+	// ConnStateAuthenticating means the connection is established and the
+	// client is waiting for the backend to authenticate it.
+	ConnStateAuthenticating
+
+	// ConnStateAuthenticated means the connection is fully usable.
+	ConnStateAuthenticated
+
+	// ConnStateWaitBeforeReconnect means the connection was lost or deemed
+	// unhealthy, and the client is waiting before reconnecting.
+	ConnStateWaitBeforeReconnect
+
+	// ConnStateAny is not a real state; it can be passed to OnStateChange (or
+	// AddStateListener) to be notified of every transition.
+	ConnStateAny
+)
+
+// ConnStateNames maps ConnState values to human-readable names, e.g. for
+// logging.
+var ConnStateNames = map[ConnState]string{
+	ConnStateDisconnected:        "disconnected",
+	ConnStateConnecting:          "connecting",
+	ConnStateAuthenticating:      "authenticating",
+	ConnStateAuthenticated:       "authenticated",
+	ConnStateWaitBeforeReconnect: "wait_before_reconnect",
+	ConnStateAny:                 "any",
+}
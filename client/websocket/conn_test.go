@@ -0,0 +1,91 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRunLoopReconnectsAfterDisconnect drives runLoop with a fake connect
+// func -- no real socket needed, since runLoop only depends on the
+// disconnectedC it returns -- and checks that a disconnect sends the
+// connection through ConnStateWaitBeforeReconnect and back into
+// ConnStateConnecting.
+func TestRunLoopReconnectsAfterDisconnect(t *testing.T) {
+	w := newTestWSConn()
+
+	states := make(chan ConnState, 16)
+	w.OnStateChange(ConnStateAny, func(oldState, state ConnState) {
+		states <- state
+	})
+
+	connectCalls := make(chan struct{}, 16)
+	disconnectedC := make(chan struct{})
+	connect := func(url string) <-chan struct{} {
+		connectCalls <- struct{}{}
+		return disconnectedC
+	}
+
+	go w.runLoop(connect)
+	defer w.Close()
+
+	assert.Equal(t, ConnStateConnecting, <-states)
+	<-connectCalls
+
+	close(disconnectedC)
+	assert.Equal(t, ConnStateWaitBeforeReconnect, <-states)
+	assert.Equal(t, ConnStateConnecting, <-states)
+	<-connectCalls
+}
+
+// TestRunLoopAutoReconnectCyclesHealthyConnection checks that a non-zero
+// AutoReconnect interval forces a reconnect even when connect() never
+// reports a disconnect on its own.
+func TestRunLoopAutoReconnectCyclesHealthyConnection(t *testing.T) {
+	w := newTestWSConn()
+	w.params.AutoReconnect = 10 * time.Millisecond
+
+	states := make(chan ConnState, 16)
+	w.OnStateChange(ConnStateAny, func(oldState, state ConnState) {
+		states <- state
+	})
+
+	connect := func(url string) <-chan struct{} {
+		return make(chan struct{}) // never closes on its own
+	}
+
+	go w.runLoop(connect)
+	defer w.Close()
+
+	assert.Equal(t, ConnStateConnecting, <-states)
+	assert.Equal(t, ConnStateWaitBeforeReconnect, <-states)
+	assert.Equal(t, ConnStateConnecting, <-states)
+}
+
+// TestRunLoopStopsOnClose checks that closing the connection for good exits
+// runLoop instead of redialing.
+func TestRunLoopStopsOnClose(t *testing.T) {
+	w := newTestWSConn()
+
+	connectCalls := make(chan struct{}, 16)
+	connect := func(url string) <-chan struct{} {
+		connectCalls <- struct{}{}
+		return make(chan struct{})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.runLoop(connect)
+		close(done)
+	}()
+
+	<-connectCalls
+	assert.NoError(t, w.Close())
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runLoop did not return after Close")
+	}
+}
@@ -0,0 +1,144 @@
+// Package depth implements the sorted price-level bookkeeping behind
+// OrderBookSubscription: buffering deltas that arrive before the REST
+// snapshot, and then applying a book's bids and asks in sequence-number
+// order.
+package depth
+
+import (
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+// Buffer holds one market's order book as two sorted slices of price
+// levels -- bids descending by price, asks ascending -- plus any deltas
+// that arrived before a snapshot was applied. Updates locate their price
+// level with a binary search, so they're O(log n) plus the O(n) slice
+// insert/delete already implied by keeping the levels sorted.
+type Buffer struct {
+	bids []common.PriceLevel
+	asks []common.PriceLevel
+
+	pending []common.Diff
+
+	lastID uint64
+	primed bool
+}
+
+// New returns an empty Buffer.
+func New() *Buffer {
+	return &Buffer{}
+}
+
+// Buffering reports whether the Buffer is still waiting for a snapshot,
+// i.e. deltas are being queued rather than applied.
+func (b *Buffer) Buffering() bool {
+	return !b.primed
+}
+
+// BufferDelta queues a delta received before the snapshot was available.
+func (b *Buffer) BufferDelta(d common.Diff) {
+	b.pending = append(b.pending, d)
+}
+
+// ApplySnapshot seeds the Buffer from a REST snapshot, drops any buffered
+// deltas that the snapshot already reflects, and applies the rest in
+// order. It returns the resulting book.
+func (b *Buffer) ApplySnapshot(snapshot common.Book) common.Book {
+	b.bids = append([]common.PriceLevel(nil), snapshot.Bids...)
+	b.asks = append([]common.PriceLevel(nil), snapshot.Asks...)
+	b.lastID = snapshot.SeqNum
+	b.primed = true
+
+	remaining := b.pending[:0]
+	for _, d := range b.pending {
+		if d.LastID <= b.lastID {
+			continue
+		}
+		remaining = append(remaining, d)
+	}
+	b.pending = nil
+
+	for _, d := range remaining {
+		b.Apply(d)
+	}
+
+	return b.Book(snapshot.MarketID)
+}
+
+// Gap reports whether d cannot be applied directly onto the current book
+// because one or more updates were missed in between.
+func (b *Buffer) Gap(d common.Diff) bool {
+	return d.FirstID > b.lastID+1
+}
+
+// Apply merges d's bid/ask levels into the book and advances lastID. The
+// caller is responsible for calling Gap first and re-snapshotting instead
+// if it returns true.
+func (b *Buffer) Apply(d common.Diff) {
+	b.bids = mergeLevels(b.bids, d.Bids, true)
+	b.asks = mergeLevels(b.asks, d.Asks, false)
+	b.lastID = d.LastID
+}
+
+// Book returns the current state as a common.Book.
+func (b *Buffer) Book(marketID common.MarketID) common.Book {
+	return common.Book{
+		MarketID: marketID,
+		Bids:     append([]common.PriceLevel(nil), b.bids...),
+		Asks:     append([]common.PriceLevel(nil), b.asks...),
+		SeqNum:   b.lastID,
+	}
+}
+
+// price parses a PriceLevel's decimal-string Price. An unparseable price
+// (which shouldn't happen against a real exchange) sorts as zero rather
+// than panicking or corrupting the rest of the book.
+func price(p string) decimal.Decimal {
+	d, err := decimal.NewFromString(p)
+	if err != nil {
+		return decimal.Zero
+	}
+	return d
+}
+
+// mergeLevels applies updates onto levels, which must already be sorted
+// (descending if desc, else ascending) by numeric price. A "0" amount
+// removes the level.
+func mergeLevels(levels []common.PriceLevel, updates []common.PriceLevel, desc bool) []common.PriceLevel {
+	less := func(a, c decimal.Decimal) bool {
+		if desc {
+			return a.GreaterThan(c)
+		}
+		return a.LessThan(c)
+	}
+
+	for _, u := range updates {
+		uPrice := price(u.Price)
+
+		i := sort.Search(len(levels), func(i int) bool {
+			return !less(price(levels[i].Price), uPrice)
+		})
+
+		found := i < len(levels) && price(levels[i].Price).Equal(uPrice)
+
+		if u.Amount == "0" {
+			if found {
+				levels = append(levels[:i], levels[i+1:]...)
+			}
+			continue
+		}
+
+		if found {
+			levels[i].Amount = u.Amount
+		} else {
+			levels = append(levels, common.PriceLevel{})
+			copy(levels[i+1:], levels[i:])
+			levels[i] = u
+		}
+	}
+
+	return levels
+}
@@ -0,0 +1,98 @@
+package depth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+func TestBufferBuffersUntilSnapshot(t *testing.T) {
+	b := New()
+	assert.True(t, b.Buffering())
+
+	b.BufferDelta(common.Diff{MarketID: 1, FirstID: 1, LastID: 1})
+
+	book := b.ApplySnapshot(common.Book{
+		MarketID: 1,
+		Bids:     []common.PriceLevel{{Price: "100", Amount: "1"}},
+		SeqNum:   5,
+	})
+
+	assert.False(t, b.Buffering())
+	assert.Equal(t, uint64(5), book.SeqNum)
+	assert.Equal(t, []common.PriceLevel{{Price: "100", Amount: "1"}}, book.Bids)
+}
+
+func TestBufferApplySnapshotDropsDeltasAlreadyCovered(t *testing.T) {
+	b := New()
+	b.BufferDelta(common.Diff{FirstID: 1, LastID: 3, Bids: []common.PriceLevel{{Price: "100", Amount: "9"}}})
+	b.BufferDelta(common.Diff{FirstID: 4, LastID: 4, Bids: []common.PriceLevel{{Price: "100", Amount: "2"}}})
+
+	book := b.ApplySnapshot(common.Book{
+		Bids:   []common.PriceLevel{{Price: "100", Amount: "1"}},
+		SeqNum: 3,
+	})
+
+	// The first buffered delta (LastID 3) is already reflected in the
+	// snapshot and must be skipped; only the second (LastID 4) applies.
+	assert.Equal(t, uint64(4), book.SeqNum)
+	assert.Equal(t, []common.PriceLevel{{Price: "100", Amount: "2"}}, book.Bids)
+}
+
+func TestBufferGap(t *testing.T) {
+	b := New()
+	b.ApplySnapshot(common.Book{SeqNum: 10})
+
+	assert.False(t, b.Gap(common.Diff{FirstID: 11, LastID: 12}))
+	assert.True(t, b.Gap(common.Diff{FirstID: 12, LastID: 13}))
+}
+
+func TestBufferApplyMergesNumerically(t *testing.T) {
+	b := New()
+	b.ApplySnapshot(common.Book{
+		Bids: []common.PriceLevel{
+			{Price: "10", Amount: "1"},
+			{Price: "9", Amount: "1"},
+		},
+		Asks: []common.PriceLevel{
+			{Price: "11", Amount: "1"},
+			{Price: "100", Amount: "1"},
+		},
+	})
+
+	// "9" sorts after "100" lexically but must sort before it numerically;
+	// this exercises that mergeLevels compares by parsed decimal value.
+	b.Apply(common.Diff{
+		Bids:   []common.PriceLevel{{Price: "100", Amount: "2"}},
+		Asks:   []common.PriceLevel{{Price: "9", Amount: "2"}},
+		LastID: 1,
+	})
+
+	book := b.Book(1)
+	assert.Equal(t, []common.PriceLevel{
+		{Price: "100", Amount: "2"},
+		{Price: "10", Amount: "1"},
+		{Price: "9", Amount: "1"},
+	}, book.Bids)
+	assert.Equal(t, []common.PriceLevel{
+		{Price: "9", Amount: "2"},
+		{Price: "11", Amount: "1"},
+		{Price: "100", Amount: "1"},
+	}, book.Asks)
+}
+
+func TestBufferApplyRemovesZeroAmountLevel(t *testing.T) {
+	b := New()
+	b.ApplySnapshot(common.Book{
+		Bids: []common.PriceLevel{{Price: "10", Amount: "1"}},
+	})
+
+	b.Apply(common.Diff{
+		Bids:   []common.PriceLevel{{Price: "10", Amount: "0"}},
+		LastID: 1,
+	})
+
+	assert.Empty(t, b.Book(1).Bids)
+}
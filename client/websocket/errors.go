@@ -0,0 +1,38 @@
+package websocket
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrHeartbeatTimeout is the cause reported (via OnError, with
+// disconnecting=true) when no pong -- or any frame, since the read deadline
+// is extended on every frame -- arrives within WSParams.PongWait.
+var ErrHeartbeatTimeout = errors.New("websocket: heartbeat timeout")
+
+// errRequestTimeout is returned by a batch operation's per-entry result when
+// the exchange doesn't acknowledge it within the request's timeout.
+var errRequestTimeout = errors.New("websocket: request timed out")
+
+// errNotConnected is returned when a request can't be sent because there's
+// no live connection (e.g. mid-reconnect). It's not returned to callers
+// across a reconnect: resendInFlight resends the request once a new
+// connection authenticates.
+var errNotConnected = errors.New("websocket: not connected")
+
+// ErrBookNotReady is returned by OrderBookSubscription.GetBook when
+// marketID has been subscribed to but no REST snapshot has synced in yet.
+var ErrBookNotReady = errors.New("websocket: order book not ready")
+
+// ErrUnsupportedOrderFeature is returned by TradeClient.PlaceOrder when one
+// or more fields of the requested order aren't supported by the market's
+// exchange, per GetExchangeCapabilities. Fields lists the offending
+// PlaceOrderParams field names so the caller can downgrade the request
+// instead of resubmitting blind.
+type ErrUnsupportedOrderFeature struct {
+	Fields []string
+}
+
+func (e *ErrUnsupportedOrderFeature) Error() string {
+	return "unsupported order feature(s): " + strings.Join(e.Fields, ", ")
+}
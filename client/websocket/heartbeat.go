@@ -0,0 +1,90 @@
+package websocket
+
+import (
+	"time"
+
+	gorillaws "github.com/gorilla/websocket"
+)
+
+const (
+	defaultPingInterval = 30 * time.Second
+	defaultPongWait     = 60 * time.Second
+	defaultWriteWait    = 10 * time.Second
+)
+
+// startHeartbeat writes a ping frame every PingInterval and watches for a
+// pong (or any frame -- the read deadline is extended in the pong handler
+// and on every read) within PongWait. If the deadline is ever missed, or a
+// ping can't be written, onTimeout is called with ErrHeartbeatTimeout (or
+// the write error) and the goroutine exits; the caller is responsible for
+// transitioning to ConnStateWaitBeforeReconnect. It returns a func that
+// stops the heartbeat goroutine.
+func (w *wsConn) startHeartbeat(onTimeout func(error)) (stop func()) {
+	stopC := make(chan struct{})
+
+	w.writeMtx.Lock()
+	conn := w.conn
+	w.writeMtx.Unlock()
+
+	w.extendReadDeadline()
+	if conn != nil {
+		conn.SetPongHandler(func(string) error {
+			return w.extendReadDeadline()
+		})
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.params.PingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopC:
+				return
+
+			case <-ticker.C:
+				w.writeMtx.Lock()
+				conn := w.conn
+				w.writeMtx.Unlock()
+
+				if conn == nil {
+					continue
+				}
+
+				conn.SetWriteDeadline(time.Now().Add(w.params.WriteWait))
+				if err := conn.WriteMessage(gorillaws.PingMessage, nil); err != nil {
+					onTimeout(err)
+					return
+				}
+
+				if w.pongOverdue() {
+					onTimeout(ErrHeartbeatTimeout)
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stopC)
+	}
+}
+
+func (w *wsConn) extendReadDeadline() error {
+	w.writeMtx.Lock()
+	w.lastFrame = time.Now()
+	conn := w.conn
+	pongWait := w.params.PongWait
+	w.writeMtx.Unlock()
+
+	if conn != nil {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	}
+	return nil
+}
+
+func (w *wsConn) pongOverdue() bool {
+	w.writeMtx.Lock()
+	defer w.writeMtx.Unlock()
+	return time.Since(w.lastFrame) > w.params.PongWait
+}
@@ -0,0 +1,47 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWSConn() *wsConn {
+	return newWSConn(&WSParams{URL: "ws://example.invalid"})
+}
+
+func TestExtendReadDeadlineWithoutConnUpdatesLastFrame(t *testing.T) {
+	w := newTestWSConn()
+
+	before := time.Now()
+	assert.NoError(t, w.extendReadDeadline())
+
+	w.writeMtx.Lock()
+	lastFrame := w.lastFrame
+	w.writeMtx.Unlock()
+
+	assert.False(t, lastFrame.Before(before))
+}
+
+func TestPongOverdueWithoutTraffic(t *testing.T) {
+	w := newTestWSConn()
+	w.params.PongWait = 10 * time.Millisecond
+
+	assert.NoError(t, w.extendReadDeadline())
+	assert.False(t, w.pongOverdue(), "should not be overdue immediately after a frame")
+
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, w.pongOverdue(), "should be overdue once PongWait has elapsed with no traffic")
+}
+
+func TestStartHeartbeatStopsCleanly(t *testing.T) {
+	w := newTestWSConn()
+	w.params.PingInterval = time.Hour
+
+	var timeoutErr error
+	stop := w.startHeartbeat(func(err error) { timeoutErr = err })
+	stop()
+
+	assert.NoError(t, timeoutErr)
+}
@@ -0,0 +1,194 @@
+package websocket
+
+import (
+	"sync"
+
+	"github.com/juju/errors"
+
+	"github.com/khcchiu/cw-sdk-go/client/rest"
+	"github.com/khcchiu/cw-sdk-go/client/websocket/depth"
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+// BookUpdateCallback is the signature for callbacks registered via
+// OnBookUpdate.
+type BookUpdateCallback func(book common.Book, diff common.Diff)
+
+// ResyncCallback is the signature for callbacks registered via OnResync; it
+// fires whenever a gap forces a fresh REST snapshot.
+type ResyncCallback func(marketID common.MarketID)
+
+type marketBook struct {
+	buf *depth.Buffer
+
+	// resyncing is set while a gap-triggered resync is in flight, so
+	// deltas that arrive before the new snapshot lands are buffered
+	// instead of applied against the known-stale book, and a second gap
+	// can't launch a redundant concurrent resync.
+	resyncing bool
+}
+
+// OrderBookSubscription delivers a consistent, gap-free L2 order book per
+// market: it subscribes to the delta stream first, buffers deltas by
+// sequence number, fetches a REST snapshot, drops buffered deltas the
+// snapshot already covers, and applies the rest in order. If a gap is ever
+// detected in the live delta stream it re-snapshots automatically.
+type OrderBookSubscription struct {
+	sc   *StreamConn
+	rest *rest.Client
+
+	mtx   sync.Mutex
+	books map[common.MarketID]*marketBook
+
+	updateListenersMtx sync.Mutex
+	updateListeners    map[common.MarketID][]BookUpdateCallback
+	resyncListeners    []ResyncCallback
+}
+
+// NewOrderBookSubscription creates an OrderBookSubscription that pulls
+// deltas off sc and snapshots over restClient.
+func NewOrderBookSubscription(sc *StreamConn, restClient *rest.Client) *OrderBookSubscription {
+	if restClient == nil {
+		restClient = rest.NewClient()
+	}
+
+	obs := &OrderBookSubscription{
+		sc:              sc,
+		rest:            restClient,
+		books:           make(map[common.MarketID]*marketBook),
+		updateListeners: make(map[common.MarketID][]BookUpdateCallback),
+	}
+
+	sc.OnDelta(obs.onDelta)
+
+	return obs
+}
+
+// Subscribe starts tracking marketID's order book: it subscribes to the
+// market's delta stream and kicks off the initial snapshot fetch.
+func (obs *OrderBookSubscription) Subscribe(marketID common.MarketID) error {
+	obs.mtx.Lock()
+	if _, ok := obs.books[marketID]; ok {
+		obs.mtx.Unlock()
+		return errors.Errorf("already subscribed to market %d", marketID)
+	}
+	obs.books[marketID] = &marketBook{buf: depth.New()}
+	obs.mtx.Unlock()
+
+	go obs.resync(marketID)
+
+	return nil
+}
+
+// GetBook returns the current consistent book for marketID. It returns
+// ErrBookNotReady if marketID is subscribed but the initial REST snapshot
+// hasn't synced in yet, rather than silently returning an empty book.
+func (obs *OrderBookSubscription) GetBook(marketID common.MarketID) (common.Book, error) {
+	obs.mtx.Lock()
+	defer obs.mtx.Unlock()
+
+	mb, ok := obs.books[marketID]
+	if !ok {
+		return common.Book{}, errors.Errorf("not subscribed to market %d", marketID)
+	}
+
+	if mb.buf.Buffering() {
+		return common.Book{}, ErrBookNotReady
+	}
+
+	return mb.buf.Book(marketID), nil
+}
+
+// OnBookUpdate registers cb to be called every time marketID's book changes.
+func (obs *OrderBookSubscription) OnBookUpdate(marketID common.MarketID, cb BookUpdateCallback) {
+	obs.updateListenersMtx.Lock()
+	defer obs.updateListenersMtx.Unlock()
+	obs.updateListeners[marketID] = append(obs.updateListeners[marketID], cb)
+}
+
+// OnResync registers cb to be called whenever a gap forces a re-snapshot.
+func (obs *OrderBookSubscription) OnResync(cb ResyncCallback) {
+	obs.updateListenersMtx.Lock()
+	defer obs.updateListenersMtx.Unlock()
+	obs.resyncListeners = append(obs.resyncListeners, cb)
+}
+
+// onDelta is the entry point for deltas arriving off the stream connection.
+func (obs *OrderBookSubscription) onDelta(d common.Diff) {
+	obs.mtx.Lock()
+	mb, ok := obs.books[d.MarketID]
+	if !ok {
+		obs.mtx.Unlock()
+		return
+	}
+
+	if mb.buf.Buffering() || mb.resyncing {
+		mb.buf.BufferDelta(d)
+		obs.mtx.Unlock()
+		return
+	}
+
+	if mb.buf.Gap(d) {
+		mb.resyncing = true
+		mb.buf.BufferDelta(d)
+		obs.mtx.Unlock()
+		go obs.resync(d.MarketID)
+		obs.notifyResync(d.MarketID)
+		return
+	}
+
+	mb.buf.Apply(d)
+	book := mb.buf.Book(d.MarketID)
+	obs.mtx.Unlock()
+
+	obs.notifyUpdate(book, d)
+}
+
+// resync fetches a fresh REST snapshot for marketID and reconciles it
+// against whatever deltas have buffered up in the meantime.
+func (obs *OrderBookSubscription) resync(marketID common.MarketID) {
+	snapshot, err := obs.rest.GetOrderBookSnapshot(marketID)
+	if err != nil {
+		// Clear resyncing so the next delta re-evaluates the gap and
+		// triggers another attempt; until then it keeps buffering like any
+		// other delta that arrives while resyncing.
+		obs.mtx.Lock()
+		if mb, ok := obs.books[marketID]; ok {
+			mb.resyncing = false
+		}
+		obs.mtx.Unlock()
+		return
+	}
+
+	obs.mtx.Lock()
+	mb, ok := obs.books[marketID]
+	if !ok {
+		obs.mtx.Unlock()
+		return
+	}
+	book := mb.buf.ApplySnapshot(snapshot)
+	mb.resyncing = false
+	obs.mtx.Unlock()
+
+	obs.notifyUpdate(book, common.Diff{MarketID: marketID, LastID: book.SeqNum})
+}
+
+func (obs *OrderBookSubscription) notifyUpdate(book common.Book, diff common.Diff) {
+	obs.updateListenersMtx.Lock()
+	listeners := append([]BookUpdateCallback{}, obs.updateListeners[book.MarketID]...)
+	obs.updateListenersMtx.Unlock()
+
+	for _, cb := range listeners {
+		cb(book, diff)
+	}
+}
+
+func (obs *OrderBookSubscription) notifyResync(marketID common.MarketID) {
+	obs.updateListenersMtx.Lock()
+	listeners := append([]ResyncCallback{}, obs.resyncListeners...)
+	obs.updateListenersMtx.Unlock()
+
+	for _, cb := range listeners {
+		cb(marketID)
+	}
+}
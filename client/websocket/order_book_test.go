@@ -0,0 +1,172 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/khcchiu/cw-sdk-go/client/rest"
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+// snapshotServer serves GetOrderBookSnapshot responses built by nextBook,
+// counting how many times it was hit.
+type snapshotServer struct {
+	*httptest.Server
+	calls    int32
+	nextBook func(call int32) common.Book
+}
+
+func newSnapshotServer(t *testing.T, nextBook func(call int32) common.Book) *snapshotServer {
+	s := &snapshotServer{nextBook: nextBook}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := atomic.AddInt32(&s.calls, 1)
+		book := s.nextBook(call)
+
+		resp := struct {
+			Result struct {
+				SeqNum uint64              `json:"seqNum"`
+				Bids   []common.PriceLevel `json:"bids"`
+				Asks   []common.PriceLevel `json:"asks"`
+			} `json:"result"`
+		}{}
+		resp.Result.SeqNum = book.SeqNum
+		resp.Result.Bids = book.Bids
+		resp.Result.Asks = book.Asks
+
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	return s
+}
+
+func newTestStreamConn(t *testing.T) *StreamConn {
+	sc, err := NewStreamConn(&StreamClientParams{
+		WSParams: &WSParams{URL: "ws://example.invalid"},
+	})
+	assert.NoError(t, err)
+	return sc
+}
+
+func waitForBook(t *testing.T, updates <-chan common.Book) common.Book {
+	select {
+	case book := <-updates:
+		return book
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a book update")
+		return common.Book{}
+	}
+}
+
+func TestOrderBookSubscriptionAppliesSnapshotThenDeltas(t *testing.T) {
+	const marketID = common.MarketID(1)
+
+	srv := newSnapshotServer(t, func(call int32) common.Book {
+		return common.Book{SeqNum: 5, Bids: []common.PriceLevel{{Price: "10", Amount: "1"}}}
+	})
+	defer srv.Close()
+
+	obs := NewOrderBookSubscription(newTestStreamConn(t), &rest.Client{URL: srv.URL, HTTPClient: srv.Client()})
+
+	updates := make(chan common.Book, 10)
+	obs.OnBookUpdate(marketID, func(book common.Book, diff common.Diff) {
+		updates <- book
+	})
+
+	assert.NoError(t, obs.Subscribe(marketID))
+
+	book := waitForBook(t, updates)
+	assert.Equal(t, uint64(5), book.SeqNum)
+
+	obs.onDelta(common.Diff{
+		MarketID: marketID,
+		Bids:     []common.PriceLevel{{Price: "11", Amount: "1"}},
+		FirstID:  6,
+		LastID:   6,
+	})
+
+	book = waitForBook(t, updates)
+	assert.Equal(t, uint64(6), book.SeqNum)
+}
+
+func TestOrderBookSubscriptionGetBookNotReadyBeforeSnapshot(t *testing.T) {
+	const marketID = common.MarketID(1)
+
+	unblock := make(chan struct{})
+	srv := newSnapshotServer(t, func(call int32) common.Book {
+		<-unblock
+		return common.Book{SeqNum: 1}
+	})
+	defer srv.Close()
+
+	obs := NewOrderBookSubscription(newTestStreamConn(t), &rest.Client{URL: srv.URL, HTTPClient: srv.Client()})
+
+	assert.NoError(t, obs.Subscribe(marketID))
+
+	_, err := obs.GetBook(marketID)
+	assert.Equal(t, ErrBookNotReady, err)
+
+	close(unblock)
+}
+
+func TestOrderBookSubscriptionGetBookUnknownMarket(t *testing.T) {
+	obs := NewOrderBookSubscription(newTestStreamConn(t), rest.NewClient())
+
+	_, err := obs.GetBook(common.MarketID(99))
+	assert.Error(t, err)
+}
+
+// TestOrderBookSubscriptionGapBuffersUntilResyncCompletes reproduces the
+// race the reviewer flagged: a second gap arriving while a resync is
+// already in flight must not launch a redundant concurrent resync, and
+// deltas that arrive during the resync must be buffered rather than
+// applied against the known-stale book.
+func TestOrderBookSubscriptionGapBuffersUntilResyncCompletes(t *testing.T) {
+	const marketID = common.MarketID(1)
+
+	srv := newSnapshotServer(t, func(call int32) common.Book {
+		if call == 1 {
+			return common.Book{SeqNum: 5}
+		}
+		return common.Book{SeqNum: 10}
+	})
+	defer srv.Close()
+
+	obs := NewOrderBookSubscription(newTestStreamConn(t), &rest.Client{URL: srv.URL, HTTPClient: srv.Client()})
+
+	updates := make(chan common.Book, 10)
+	obs.OnBookUpdate(marketID, func(book common.Book, diff common.Diff) {
+		updates <- book
+	})
+
+	resyncs := make(chan common.MarketID, 10)
+	obs.OnResync(func(marketID common.MarketID) {
+		resyncs <- marketID
+	})
+
+	assert.NoError(t, obs.Subscribe(marketID))
+	book := waitForBook(t, updates)
+	assert.Equal(t, uint64(5), book.SeqNum)
+
+	// First gap: triggers a resync and marks the market as resyncing.
+	obs.onDelta(common.Diff{MarketID: marketID, FirstID: 20, LastID: 20})
+	select {
+	case <-resyncs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the gap to be reported")
+	}
+
+	// Second gap while the resync above is still in flight: must buffer,
+	// not fire a second resync.
+	obs.onDelta(common.Diff{MarketID: marketID, FirstID: 21, LastID: 21})
+
+	book = waitForBook(t, updates)
+	assert.Equal(t, uint64(21), book.SeqNum, "both buffered deltas should apply once the snapshot lands")
+
+	time.Sleep(50 * time.Millisecond)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&srv.calls), "a second gap during an in-flight resync must not trigger another REST call")
+}
@@ -0,0 +1,63 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+// WSParams holds the parameters common to every websocket connection made by
+// this package, whether it's a TradeClient or a market data StreamConn.
+type WSParams struct {
+	APIKey    string
+	SecretKey string
+	URL       string
+
+	// AutoReconnect, when non-zero, forces the connection to be cycled at
+	// this interval even if it's healthy. This guards against stealthy
+	// half-open connections and load-balancer session timeouts that are
+	// common with long-lived exchange sessions.
+	AutoReconnect time.Duration
+
+	// PingInterval is how often a ping frame is written to the connection.
+	// PongWait is how long to wait for a pong (or any frame, since the read
+	// deadline is extended on every frame) before the connection is
+	// considered dead. WriteWait bounds how long writing a single ping
+	// frame may take. Zero values fall back to 30s/60s/10s.
+	PingInterval time.Duration
+	PongWait     time.Duration
+	WriteWait    time.Duration
+}
+
+// ExchangeAuth carries the exchange-side API credentials used to authorize
+// trading on a particular market, as opposed to the Cryptowatch APIKey/
+// SecretKey in WSParams.
+type ExchangeAuth struct {
+	APIKey    string
+	APISecret string
+}
+
+// TradeSessionParams describes one market that a TradeClient should trade
+// on.
+type TradeSessionParams struct {
+	MarketParams common.MarketParams
+	ExchangeAuth *ExchangeAuth
+}
+
+// TradeClientParams holds the parameters used to create a TradeClient.
+type TradeClientParams struct {
+	WSParams      *WSParams
+	TradeSessions []*TradeSessionParams
+
+	// ExchangeCapabilities seeds which order features each market's exchange
+	// accepts; see TradeClient.SetExchangeCapabilities. Markets without an
+	// entry here default to spot-only (limit/market orders, GTC/IOC/FOK, no
+	// margin/futures/trigger) until set otherwise.
+	ExchangeCapabilities map[common.MarketID]ExchangeCapabilities
+}
+
+// SubscriptionResult reports the outcome of a subscription request.
+type SubscriptionResult struct {
+	Subscribed []string
+	Failed     []string
+}
@@ -0,0 +1,149 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/juju/errors"
+
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+// MarketUpdateCallback is the signature for callbacks registered via
+// OnMarketUpdate.
+type MarketUpdateCallback func(marketID common.MarketID)
+
+// StreamErrorCallback is the signature for callbacks registered via OnError.
+type StreamErrorCallback func(err error, disconnecting bool)
+
+// DeltaCallback is the signature for callbacks registered via OnDelta; it's
+// how OrderBookSubscription gets fed live order book deltas.
+type DeltaCallback func(d common.Diff)
+
+// wireMessage is the envelope every frame off the stream connection is
+// decoded into, so incoming deltas can be routed to OrderBookSubscription.
+type wireMessage struct {
+	Type string       `json:"type"`
+	Diff *common.Diff `json:"diff,omitempty"`
+}
+
+// StreamClientParams holds the parameters used to create a StreamConn.
+type StreamClientParams struct {
+	WSParams      *WSParams
+	Subscriptions []string
+}
+
+// StreamConn streams market data (trades, order books, etc.) for one or more
+// markets over a single websocket session.
+type StreamConn struct {
+	*wsConn
+
+	params *StreamClientParams
+
+	updateListeners []MarketUpdateCallback
+	errorListeners  []StreamErrorCallback
+	deltaListeners  []DeltaCallback
+}
+
+// NewStreamConn creates a StreamConn. The connection is not established
+// until Connect is called.
+func NewStreamConn(params *StreamClientParams) (*StreamConn, error) {
+	if params == nil || params.WSParams == nil {
+		return nil, errors.New("WSParams is required")
+	}
+
+	return &StreamConn{
+		wsConn: newWSConn(params.WSParams),
+		params: params,
+	}, nil
+}
+
+// Connect starts the connection loop; it returns immediately, and delivers
+// progress via OnStateChange/OnMarketUpdate.
+func (sc *StreamConn) Connect() {
+	go sc.runLoop(sc.dial)
+}
+
+// UpdateURL atomically swaps the URL that the next (re)connect will target,
+// without tearing down the current connection.
+func (sc *StreamConn) UpdateURL(url string) {
+	sc.wsConn.UpdateURL(url)
+}
+
+// OnMarketUpdate registers cb to be called whenever a market update message
+// is received.
+func (sc *StreamConn) OnMarketUpdate(cb MarketUpdateCallback) {
+	sc.updateListeners = append(sc.updateListeners, cb)
+}
+
+// OnError registers cb to be called whenever the connection hits an error,
+// e.g. a heartbeat timeout.
+func (sc *StreamConn) OnError(cb StreamErrorCallback) {
+	sc.errorListeners = append(sc.errorListeners, cb)
+}
+
+// OnDelta registers cb to be called for every order book delta received on
+// the connection. OrderBookSubscription registers itself this way to feed
+// its snapshot+delta reconciliation with live updates.
+func (sc *StreamConn) OnDelta(cb DeltaCallback) {
+	sc.deltaListeners = append(sc.deltaListeners, cb)
+}
+
+func (sc *StreamConn) dispatchMessage(messageType int, data []byte) {
+	var msg wireMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+
+	switch msg.Type {
+	case "diff":
+		if msg.Diff == nil {
+			return
+		}
+		for _, cb := range sc.deltaListeners {
+			cb(*msg.Diff)
+		}
+	}
+}
+
+func (sc *StreamConn) dial(url string) <-chan struct{} {
+	disconnectedC := make(chan struct{})
+
+	var once sync.Once
+	closeDisconnected := func() {
+		once.Do(func() {
+			sc.clearConn()
+			close(disconnectedC)
+		})
+	}
+
+	conn, err := sc.dialConn(url)
+	if err != nil {
+		closeDisconnected()
+		return disconnectedC
+	}
+
+	// A real subscribe handshake over conn would go here.
+	sc.setState(ConnStateAuthenticated)
+
+	var disconnectOnce sync.Once
+	disconnect := func(err error) {
+		disconnectOnce.Do(func() {
+			for _, cb := range sc.errorListeners {
+				cb(err, true)
+			}
+			closeDisconnected()
+		})
+	}
+
+	stopHeartbeat := sc.startHeartbeat(disconnect)
+
+	go sc.readLoop(conn, sc.dispatchMessage, disconnect)
+
+	go func() {
+		<-disconnectedC
+		stopHeartbeat()
+	}()
+
+	return disconnectedC
+}
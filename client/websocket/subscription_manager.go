@@ -0,0 +1,286 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	subscribeBackoffBase = 500 * time.Millisecond
+	subscribeBackoffMax  = 30 * time.Second
+)
+
+// SubscriptionStatus reports the outcome of one key passed to
+// SubscriptionManager.Subscribe.
+type SubscriptionStatus struct {
+	Key        string
+	Subscribed bool
+	Err        error
+}
+
+type subscriptionState struct {
+	refCount int
+	acked    bool
+	backoff  time.Duration
+}
+
+// subscribeBatch is the destination for a single Subscribe(...) call: all
+// of its keys report into the same out channel, but each key only counts
+// down remaining once, so the channel is closed exactly after every key in
+// the batch -- not just the first -- has been delivered.
+type subscribeBatch struct {
+	mtx       sync.Mutex
+	out       chan SubscriptionStatus
+	remaining int
+}
+
+func (b *subscribeBatch) deliver(status SubscriptionStatus) {
+	b.out <- status
+
+	b.mtx.Lock()
+	b.remaining--
+	done := b.remaining <= 0
+	b.mtx.Unlock()
+
+	if done {
+		close(b.out)
+	}
+}
+
+// pendingKey ties one key of a subscribeBatch to the batch it belongs to,
+// so ack() can deliver to the right batch without ever touching a channel
+// more than once per key.
+type pendingKey struct {
+	batch *subscribeBatch
+}
+
+// SubscriptionManager owns the desired-subscription set for a connection and
+// keeps it reconciled against the server-acknowledged set: it resubscribes
+// after every ConnStateAuthenticated transition, retries failures with
+// exponential backoff, and reference-counts keys so independent consumers
+// sharing one connection can subscribe/unsubscribe without stepping on each
+// other. This removes the OnReady/OnSubscriptionResult boilerplate callers
+// previously had to wire up by hand.
+type SubscriptionManager struct {
+	send func(keys []string) error
+
+	mtx     sync.Mutex
+	desired map[string]*subscriptionState
+	pending map[string][]*pendingKey
+
+	stateChangeMtx       sync.Mutex
+	stateChangeListeners []func()
+}
+
+// NewSubscriptionManager creates a SubscriptionManager that resubscribes
+// over tc, reconciling against the results tc reports via
+// OnSubscriptionResult.
+func NewSubscriptionManager(tc *TradeClient) *SubscriptionManager {
+	sm := &SubscriptionManager{
+		send:    tc.sendSubscribe,
+		desired: make(map[string]*subscriptionState),
+		pending: make(map[string][]*pendingKey),
+	}
+
+	tc.OnStateChange(ConnStateAuthenticated, func(oldState, state ConnState) {
+		sm.resubscribeAll()
+	})
+	tc.OnSubscriptionResult(sm.handleResult)
+
+	return sm
+}
+
+// Subscribe adds keys to the desired set (reference-counting ones already
+// present) and returns a channel that receives one SubscriptionStatus per
+// key as the server acknowledges or rejects it, then closes.
+func (sm *SubscriptionManager) Subscribe(keys ...string) <-chan SubscriptionStatus {
+	statusC := make(chan SubscriptionStatus, len(keys))
+
+	sm.mtx.Lock()
+	var toSend []string
+	var alreadyAcked []string
+	for _, key := range keys {
+		st, ok := sm.desired[key]
+		if !ok {
+			st = &subscriptionState{backoff: subscribeBackoffBase}
+			sm.desired[key] = st
+		}
+		st.refCount++
+
+		if st.acked {
+			alreadyAcked = append(alreadyAcked, key)
+		} else {
+			toSend = append(toSend, key)
+		}
+	}
+
+	if len(toSend) > 0 {
+		batch := &subscribeBatch{out: statusC, remaining: len(toSend)}
+		for _, key := range toSend {
+			sm.pending[key] = append(sm.pending[key], &pendingKey{batch: batch})
+		}
+	}
+	sm.mtx.Unlock()
+
+	// Keys that were already acked before this call can be reported
+	// straight away; they don't share a remaining-count with the pending
+	// ones, so there's no risk of double-closing statusC.
+	for _, key := range alreadyAcked {
+		statusC <- SubscriptionStatus{Key: key, Subscribed: true}
+	}
+	if len(toSend) == 0 {
+		close(statusC)
+	}
+
+	if len(toSend) > 0 {
+		go sm.sendWithBackoff(toSend)
+	}
+
+	return statusC
+}
+
+// Unsubscribe decrements keys' reference counts, dropping them from the
+// desired set once unreferenced.
+func (sm *SubscriptionManager) Unsubscribe(keys ...string) {
+	sm.mtx.Lock()
+	defer sm.mtx.Unlock()
+
+	for _, key := range keys {
+		st, ok := sm.desired[key]
+		if !ok {
+			continue
+		}
+
+		st.refCount--
+		if st.refCount <= 0 {
+			delete(sm.desired, key)
+			delete(sm.pending, key)
+		}
+	}
+}
+
+// ActiveSubscriptions returns every key currently acknowledged by the
+// server.
+func (sm *SubscriptionManager) ActiveSubscriptions() []string {
+	sm.mtx.Lock()
+	defer sm.mtx.Unlock()
+
+	keys := make([]string, 0, len(sm.desired))
+	for key, st := range sm.desired {
+		if st.acked {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// OnSubscriptionStateChange registers cb to be called once the full desired
+// set is satisfied, consolidating what would otherwise be one
+// OnSubscriptionResult callback per partial batch.
+func (sm *SubscriptionManager) OnSubscriptionStateChange(cb func()) {
+	sm.stateChangeMtx.Lock()
+	defer sm.stateChangeMtx.Unlock()
+	sm.stateChangeListeners = append(sm.stateChangeListeners, cb)
+}
+
+// resubscribeAll marks every desired key un-acked and resends it. It's
+// called on every ConnStateAuthenticated transition, since a new connection
+// has forgotten whatever the server had acknowledged before.
+func (sm *SubscriptionManager) resubscribeAll() {
+	sm.mtx.Lock()
+	keys := make([]string, 0, len(sm.desired))
+	for key, st := range sm.desired {
+		st.acked = false
+		st.backoff = subscribeBackoffBase
+		keys = append(keys, key)
+	}
+	sm.mtx.Unlock()
+
+	if len(keys) > 0 {
+		sm.sendWithBackoff(keys)
+	}
+}
+
+func (sm *SubscriptionManager) sendWithBackoff(keys []string) {
+	if err := sm.send(keys); err != nil {
+		sm.scheduleRetry(keys)
+	}
+}
+
+func (sm *SubscriptionManager) scheduleRetry(keys []string) {
+	sm.mtx.Lock()
+	waits := make(map[time.Duration][]string)
+	for _, key := range keys {
+		st, ok := sm.desired[key]
+		if !ok || st.acked {
+			continue
+		}
+		waits[st.backoff] = append(waits[st.backoff], key)
+
+		st.backoff *= 2
+		if st.backoff > subscribeBackoffMax {
+			st.backoff = subscribeBackoffMax
+		}
+	}
+	sm.mtx.Unlock()
+
+	for wait, batch := range waits {
+		batch := batch
+		time.AfterFunc(wait, func() {
+			sm.sendWithBackoff(batch)
+		})
+	}
+}
+
+// handleResult reconciles a SubscriptionResult against the desired set,
+// resolving any pending Subscribe channels and retrying failures with
+// backoff.
+func (sm *SubscriptionManager) handleResult(sr SubscriptionResult) {
+	sm.ack(sr.Subscribed, true)
+	sm.ack(sr.Failed, false)
+
+	if len(sr.Failed) > 0 {
+		sm.scheduleRetry(sr.Failed)
+	}
+}
+
+func (sm *SubscriptionManager) ack(keys []string, ok bool) {
+	sm.mtx.Lock()
+	for _, key := range keys {
+		st, exists := sm.desired[key]
+		if !exists {
+			continue
+		}
+		st.acked = ok
+
+		for _, pk := range sm.pending[key] {
+			pk.batch.deliver(SubscriptionStatus{Key: key, Subscribed: ok})
+		}
+		delete(sm.pending, key)
+	}
+	satisfied := sm.fullySatisfiedLocked()
+	sm.mtx.Unlock()
+
+	if satisfied {
+		sm.fireStateChange()
+	}
+}
+
+func (sm *SubscriptionManager) fullySatisfiedLocked() bool {
+	for _, st := range sm.desired {
+		if !st.acked {
+			return false
+		}
+	}
+	return len(sm.desired) > 0
+}
+
+func (sm *SubscriptionManager) fireStateChange() {
+	sm.stateChangeMtx.Lock()
+	listeners := append([]func(){}, sm.stateChangeListeners...)
+	sm.stateChangeMtx.Unlock()
+
+	for _, cb := range listeners {
+		cb()
+	}
+}
@@ -0,0 +1,153 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSubscriptionManager() *SubscriptionManager {
+	return &SubscriptionManager{
+		send:    func(keys []string) error { return nil },
+		desired: make(map[string]*subscriptionState),
+		pending: make(map[string][]*pendingKey),
+	}
+}
+
+func drain(statusC <-chan SubscriptionStatus) []SubscriptionStatus {
+	var got []SubscriptionStatus
+	for s := range statusC {
+		got = append(got, s)
+	}
+	return got
+}
+
+func TestSubscriptionManagerSubscribeDeliversOneStatusPerKey(t *testing.T) {
+	sm := newTestSubscriptionManager()
+
+	statusC := sm.Subscribe("a", "b")
+	sm.handleResult(SubscriptionResult{Subscribed: []string{"a", "b"}})
+
+	got := drain(statusC)
+	assert.ElementsMatch(t, []SubscriptionStatus{
+		{Key: "a", Subscribed: true},
+		{Key: "b", Subscribed: true},
+	}, got)
+}
+
+// TestSubscriptionManagerAckKeysSeparately reproduces the batch acked one key
+// at a time: both keys of a single Subscribe call share the same out
+// channel, so acking them in separate calls must not close the channel
+// until the second one lands.
+func TestSubscriptionManagerAckKeysSeparately(t *testing.T) {
+	sm := newTestSubscriptionManager()
+
+	statusC := sm.Subscribe("a", "b")
+	sm.ack([]string{"a"}, true)
+	sm.ack([]string{"b"}, true)
+
+	got := drain(statusC)
+	assert.ElementsMatch(t, []SubscriptionStatus{
+		{Key: "a", Subscribed: true},
+		{Key: "b", Subscribed: true},
+	}, got)
+}
+
+func TestSubscriptionManagerAlreadyAckedKeyReturnsImmediately(t *testing.T) {
+	sm := newTestSubscriptionManager()
+
+	statusC := sm.Subscribe("a")
+	sm.ack([]string{"a"}, true)
+	assert.Equal(t, []SubscriptionStatus{{Key: "a", Subscribed: true}}, drain(statusC))
+
+	statusC2 := sm.Subscribe("a")
+	assert.Equal(t, []SubscriptionStatus{{Key: "a", Subscribed: true}}, drain(statusC2))
+}
+
+func TestSubscriptionManagerUnsubscribeDropsKeyAtZeroRefCount(t *testing.T) {
+	sm := newTestSubscriptionManager()
+
+	sm.Subscribe("a")
+	sm.Subscribe("a")
+
+	sm.Unsubscribe("a")
+	sm.mtx.Lock()
+	_, ok := sm.desired["a"]
+	sm.mtx.Unlock()
+	assert.True(t, ok, "key should still be desired at refcount 1")
+
+	sm.Unsubscribe("a")
+	sm.mtx.Lock()
+	_, ok = sm.desired["a"]
+	sm.mtx.Unlock()
+	assert.False(t, ok, "key should be dropped at refcount 0")
+}
+
+func TestSubscriptionManagerFiresStateChangeWhenFullySatisfied(t *testing.T) {
+	sm := newTestSubscriptionManager()
+
+	// Buffered generously: once satisfied, handleResult's ack(Subscribed)
+	// and ack(Failed) sub-calls each re-check the fully-satisfied condition
+	// and may both fire, so more than one signal landing here is expected.
+	fired := make(chan struct{}, 4)
+	sm.OnSubscriptionStateChange(func() {
+		fired <- struct{}{}
+	})
+
+	statusC := sm.Subscribe("a", "b")
+	sm.handleResult(SubscriptionResult{Subscribed: []string{"a"}})
+
+	select {
+	case <-fired:
+		t.Fatal("state change fired before every key was acked")
+	default:
+	}
+
+	sm.handleResult(SubscriptionResult{Subscribed: []string{"b"}})
+	<-fired
+
+	drain(statusC)
+}
+
+// TestSubscriptionManagerWiredToTradeClientResult reproduces a real
+// NewSubscriptionManager(tc) wiring -- rather than a standalone
+// SubscriptionManager harness -- to check that a "subscriptionResult" frame
+// handed to TradeClient.dispatchMessage actually reaches handleResult and
+// resolves the pending Subscribe channel.
+func TestSubscriptionManagerWiredToTradeClientResult(t *testing.T) {
+	tc := newTestTradeClient(t)
+	sm := NewSubscriptionManager(tc)
+
+	statusC := sm.Subscribe("a")
+
+	data, err := json.Marshal(tradeWireMessage{
+		Type:               "subscriptionResult",
+		SubscriptionResult: &SubscriptionResult{Subscribed: []string{"a"}},
+	})
+	assert.NoError(t, err)
+
+	tc.dispatchMessage(1, data)
+
+	assert.Equal(t, []SubscriptionStatus{{Key: "a", Subscribed: true}}, drain(statusC))
+}
+
+// TestSubscriptionManagerWiredToTradeClientAuthenticated checks the other
+// half of the same wiring: NewSubscriptionManager registers resubscribeAll
+// against tc's real ConnStateAuthenticated transition, so every desired key
+// is marked un-acked again once the underlying connection cycles.
+func TestSubscriptionManagerWiredToTradeClientAuthenticated(t *testing.T) {
+	tc := newTestTradeClient(t)
+	sm := NewSubscriptionManager(tc)
+
+	sm.Subscribe("a")
+	sm.ack([]string{"a"}, true)
+
+	tc.setState(ConnStateAuthenticating)
+	tc.setState(ConnStateAuthenticated)
+
+	sm.mtx.Lock()
+	acked := sm.desired["a"].acked
+	sm.mtx.Unlock()
+	assert.False(t, acked, "resubscribeAll should mark every desired key un-acked on reauthentication")
+}
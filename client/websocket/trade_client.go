@@ -0,0 +1,426 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/juju/errors"
+
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+// ErrorCallback is the signature for callbacks registered via OnError.
+type ErrorCallback func(marketID common.MarketID, err error, disconnecting bool)
+
+// SubscriptionResultCallback is the signature for callbacks registered via
+// OnSubscriptionResult.
+type SubscriptionResultCallback func(sr SubscriptionResult)
+
+// tradeWireMessage is the envelope for every request/response frame on a
+// TradeClient's connection. Outgoing requests set Type plus whichever
+// fields that request needs; incoming responses correlate back to the
+// pending request via CorrelationID, except "authResult" and
+// "subscriptionResult", which aren't tied to a single request.
+type tradeWireMessage struct {
+	Type          string `json:"type"`
+	CorrelationID string `json:"correlationId,omitempty"`
+
+	// auth request fields.
+	APIKey    string        `json:"apiKey,omitempty"`
+	SecretKey string        `json:"secretKey,omitempty"`
+	Sessions  []authSession `json:"sessions,omitempty"`
+
+	// placeOrder/cancelOrder/getOrders request fields.
+	MarketID common.MarketID          `json:"marketId,omitempty"`
+	Order    *common.PlaceOrderParams `json:"order,omitempty"`
+	OrderID  string                   `json:"orderId,omitempty"`
+
+	// response fields.
+	Success            bool                `json:"success,omitempty"`
+	Error              string              `json:"error,omitempty"`
+	PlacedOrder        *common.PlacedOrder `json:"placedOrder,omitempty"`
+	Orders             []common.Order      `json:"orders,omitempty"`
+	Balances           common.Balances     `json:"balances,omitempty"`
+	SubscriptionResult *SubscriptionResult `json:"subscriptionResult,omitempty"`
+}
+
+// authSession carries one market's exchange-side credentials as part of the
+// "auth" request; a zero APIKey/APISecret means fall back on the
+// Cryptowatch-managed keys for that market.
+type authSession struct {
+	MarketID  common.MarketID `json:"marketId"`
+	APIKey    string          `json:"apiKey,omitempty"`
+	APISecret string          `json:"apiSecret,omitempty"`
+}
+
+// inFlightRequest is a request that has been sent but not yet acknowledged.
+// resend re-sends it, under the same correlation ID, once the connection
+// comes back up and is authenticated, so callers of PlaceOrder/CancelOrder
+// never see a spurious error just because the connection cycled; respC is
+// where the matching response is delivered.
+type inFlightRequest struct {
+	resend func()
+	respC  chan tradeWireMessage
+}
+
+// TradeClient lets callers place and cancel orders, and query balances and
+// open orders, on one or more markets over a single authenticated websocket
+// session.
+type TradeClient struct {
+	*wsConn
+
+	params *TradeClientParams
+
+	// CancelAllConcurrency bounds how many cancellations CancelAllOrders
+	// fans out at once when falling back to per-order cancellation. Zero
+	// means defaultCancelAllConcurrency.
+	CancelAllConcurrency int
+
+	readyListeners  []func()
+	errorListeners  []ErrorCallback
+	subResListeners []SubscriptionResultCallback
+
+	inFlightMtx sync.Mutex
+	inFlight    map[string]*inFlightRequest
+
+	capabilitiesMtx sync.Mutex
+	capabilities    map[common.MarketID]ExchangeCapabilities
+}
+
+// NewTradeClient creates a TradeClient for the given markets. The connection
+// is not established until Connect is called.
+func NewTradeClient(params *TradeClientParams) (*TradeClient, error) {
+	if params == nil || params.WSParams == nil {
+		return nil, errors.New("WSParams is required")
+	}
+
+	capabilities := make(map[common.MarketID]ExchangeCapabilities, len(params.ExchangeCapabilities))
+	for marketID, caps := range params.ExchangeCapabilities {
+		capabilities[marketID] = caps
+	}
+
+	tc := &TradeClient{
+		wsConn:       newWSConn(params.WSParams),
+		params:       params,
+		inFlight:     make(map[string]*inFlightRequest),
+		capabilities: capabilities,
+	}
+
+	tc.OnStateChange(ConnStateAuthenticated, tc.resendInFlight)
+
+	return tc, nil
+}
+
+// Connect starts the connection loop; it returns immediately, and delivers
+// progress via OnStateChange/OnReady/OnError.
+func (tc *TradeClient) Connect() {
+	go tc.runLoop(tc.dial)
+}
+
+// UpdateURL atomically swaps the URL that the next (re)connect will target,
+// without tearing down the current connection.
+func (tc *TradeClient) UpdateURL(url string) {
+	tc.wsConn.UpdateURL(url)
+}
+
+// OnReady registers cb to be called once the client is authenticated and
+// ready to trade.
+func (tc *TradeClient) OnReady(cb func()) {
+	tc.readyListeners = append(tc.readyListeners, cb)
+}
+
+// OnError registers cb to be called whenever a request fails. disconnecting
+// indicates that the error is also going to tear down the connection.
+func (tc *TradeClient) OnError(cb ErrorCallback) {
+	tc.errorListeners = append(tc.errorListeners, cb)
+}
+
+// OnSubscriptionResult registers cb to be called whenever the backend
+// reports which of the requested subscriptions succeeded or failed.
+func (tc *TradeClient) OnSubscriptionResult(cb SubscriptionResultCallback) {
+	tc.subResListeners = append(tc.subResListeners, cb)
+}
+
+// sendSubscribe sends a subscription request for keys over the current
+// connection; it's the send func SubscriptionManager retries with backoff.
+func (tc *TradeClient) sendSubscribe(keys []string) error {
+	return tc.writeJSON(tradeWireMessage{
+		Type:          "subscribe",
+		CorrelationID: uuid.New().String(),
+	})
+}
+
+func (tc *TradeClient) dial(url string) <-chan struct{} {
+	disconnectedC := make(chan struct{})
+
+	var once sync.Once
+	closeDisconnected := func() {
+		once.Do(func() {
+			tc.clearConn()
+			close(disconnectedC)
+		})
+	}
+
+	conn, err := tc.dialConn(url)
+	if err != nil {
+		closeDisconnected()
+		return disconnectedC
+	}
+
+	tc.setState(ConnStateAuthenticating)
+
+	var disconnectOnce sync.Once
+	disconnect := func(err error) {
+		disconnectOnce.Do(func() {
+			for _, cb := range tc.errorListeners {
+				cb(0, err, true)
+			}
+			closeDisconnected()
+		})
+	}
+
+	if err := tc.sendAuth(); err != nil {
+		disconnect(err)
+		return disconnectedC
+	}
+
+	stopHeartbeat := tc.startHeartbeat(disconnect)
+
+	go tc.readLoop(conn, tc.dispatchMessage, disconnect)
+
+	go func() {
+		<-disconnectedC
+		stopHeartbeat()
+	}()
+
+	return disconnectedC
+}
+
+// sendAuth sends the exchange session credentials for every configured
+// TradeSession; the connection isn't transitioned to ConnStateAuthenticated
+// until the backend acknowledges with an "authResult" response.
+func (tc *TradeClient) sendAuth() error {
+	sessions := make([]authSession, 0, len(tc.params.TradeSessions))
+	for _, s := range tc.params.TradeSessions {
+		session := authSession{MarketID: s.MarketParams.ID}
+		if s.ExchangeAuth != nil {
+			session.APIKey = s.ExchangeAuth.APIKey
+			session.APISecret = s.ExchangeAuth.APISecret
+		}
+		sessions = append(sessions, session)
+	}
+
+	return tc.writeJSON(tradeWireMessage{
+		Type:      "auth",
+		APIKey:    tc.params.WSParams.APIKey,
+		SecretKey: tc.params.WSParams.SecretKey,
+		Sessions:  sessions,
+	})
+}
+
+// dispatchMessage routes a decoded frame to whatever's waiting for it: the
+// auth handshake, a subscription result listener, or a pending in-flight
+// request matched by CorrelationID.
+func (tc *TradeClient) dispatchMessage(messageType int, data []byte) {
+	var msg tradeWireMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+
+	switch msg.Type {
+	case "authResult":
+		tc.handleAuthResult(msg)
+	case "subscriptionResult":
+		if msg.SubscriptionResult != nil {
+			for _, cb := range tc.subResListeners {
+				cb(*msg.SubscriptionResult)
+			}
+		}
+	default:
+		tc.completeInFlight(msg)
+	}
+}
+
+func (tc *TradeClient) handleAuthResult(msg tradeWireMessage) {
+	if !msg.Success {
+		for _, cb := range tc.errorListeners {
+			cb(0, errors.New(msg.Error), false)
+		}
+		return
+	}
+
+	tc.setState(ConnStateAuthenticated)
+
+	for _, cb := range tc.readyListeners {
+		cb()
+	}
+}
+
+func (tc *TradeClient) completeInFlight(msg tradeWireMessage) {
+	tc.inFlightMtx.Lock()
+	req, ok := tc.inFlight[msg.CorrelationID]
+	tc.inFlightMtx.Unlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case req.respC <- msg:
+	default:
+		// The caller already gave up (e.g. timed out); nothing to deliver
+		// to.
+	}
+}
+
+// resendInFlight re-sends every pending request once the connection
+// (re)authenticates, so a reconnect mid-request is invisible to the caller
+// blocked on its response.
+func (tc *TradeClient) resendInFlight(oldState, state ConnState) {
+	tc.inFlightMtx.Lock()
+	resends := make([]func(), 0, len(tc.inFlight))
+	for _, req := range tc.inFlight {
+		resends = append(resends, req.resend)
+	}
+	tc.inFlightMtx.Unlock()
+
+	for _, resend := range resends {
+		resend()
+	}
+}
+
+// trackInFlight registers a pending request under correlationID: resend is
+// called to re-send it across a reconnect, and the returned channel
+// receives the matching response whenever one arrives. done must be called
+// once the caller is no longer waiting, so the entry doesn't leak.
+func (tc *TradeClient) trackInFlight(correlationID string, resend func()) (respC chan tradeWireMessage, done func()) {
+	respC = make(chan tradeWireMessage, 1)
+
+	tc.inFlightMtx.Lock()
+	tc.inFlight[correlationID] = &inFlightRequest{resend: resend, respC: respC}
+	tc.inFlightMtx.Unlock()
+
+	return respC, func() {
+		tc.inFlightMtx.Lock()
+		delete(tc.inFlight, correlationID)
+		tc.inFlightMtx.Unlock()
+	}
+}
+
+// GetOrders returns the resting orders on marketID.
+func (tc *TradeClient) GetOrders(marketID common.MarketID) ([]common.Order, error) {
+	correlationID := uuid.New().String()
+	send := func() error {
+		return tc.writeJSON(tradeWireMessage{
+			Type:          "getOrders",
+			CorrelationID: correlationID,
+			MarketID:      marketID,
+		})
+	}
+
+	respC, done := tc.trackInFlight(correlationID, func() { send() })
+	defer done()
+
+	if err := send(); err != nil {
+		return nil, err
+	}
+
+	msg := <-respC
+	if !msg.Success {
+		return nil, errors.New(msg.Error)
+	}
+	return msg.Orders, nil
+}
+
+// GetBalances returns the account balances visible to this session.
+func (tc *TradeClient) GetBalances() (common.Balances, error) {
+	correlationID := uuid.New().String()
+	send := func() error {
+		return tc.writeJSON(tradeWireMessage{
+			Type:          "getBalances",
+			CorrelationID: correlationID,
+		})
+	}
+
+	respC, done := tc.trackInFlight(correlationID, func() { send() })
+	defer done()
+
+	if err := send(); err != nil {
+		return nil, err
+	}
+
+	msg := <-respC
+	if !msg.Success {
+		return nil, errors.New(msg.Error)
+	}
+	return msg.Balances, nil
+}
+
+// PlaceOrder places an order and returns it once the exchange has
+// acknowledged it. If the connection cycles while the request is pending,
+// it's retried transparently using the same correlation ID.
+func (tc *TradeClient) PlaceOrder(params common.PlaceOrderParams) (common.PlacedOrder, error) {
+	var order common.PlacedOrder
+
+	caps, err := tc.GetExchangeCapabilities(params.MarketID)
+	if err != nil {
+		return order, err
+	}
+
+	if err := validatePlaceOrderParams(params, caps); err != nil {
+		return order, err
+	}
+
+	correlationID := uuid.New().String()
+	send := func() error {
+		return tc.writeJSON(tradeWireMessage{
+			Type:          "placeOrder",
+			CorrelationID: correlationID,
+			MarketID:      params.MarketID,
+			Order:         &params,
+		})
+	}
+
+	respC, done := tc.trackInFlight(correlationID, func() { send() })
+	defer done()
+
+	if err := send(); err != nil {
+		return order, err
+	}
+
+	msg := <-respC
+	if !msg.Success {
+		return order, errors.New(msg.Error)
+	}
+	if msg.PlacedOrder != nil {
+		order = *msg.PlacedOrder
+	}
+	return order, nil
+}
+
+// CancelOrder cancels a resting order. Like PlaceOrder, it's retried
+// transparently across a connection cycle.
+func (tc *TradeClient) CancelOrder(params common.CancelOrderParams) error {
+	correlationID := uuid.New().String()
+	send := func() error {
+		return tc.writeJSON(tradeWireMessage{
+			Type:          "cancelOrder",
+			CorrelationID: correlationID,
+			MarketID:      params.MarketID,
+			OrderID:       params.OrderID,
+		})
+	}
+
+	respC, done := tc.trackInFlight(correlationID, func() { send() })
+	defer done()
+
+	if err := send(); err != nil {
+		return err
+	}
+
+	msg := <-respC
+	if !msg.Success {
+		return errors.New(msg.Error)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/khcchiu/cw-sdk-go/common"
+)
+
+func newTestTradeClient(t *testing.T) *TradeClient {
+	tc, err := NewTradeClient(&TradeClientParams{
+		WSParams: &WSParams{URL: "ws://example.invalid"},
+	})
+	assert.NoError(t, err)
+	return tc
+}
+
+// TestTrackInFlightStaysRegisteredUntilDone reproduces what the reviewer
+// flagged: a request must remain in tc.inFlight for as long as the caller is
+// waiting on its response, so a reconnect mid-request can actually find it
+// and resend it. Calling done() early (e.g. from a synchronous caller that
+// never awaited a response) is what made resendInFlight structurally dead.
+func TestTrackInFlightStaysRegisteredUntilDone(t *testing.T) {
+	tc := newTestTradeClient(t)
+
+	respC, done := tc.trackInFlight("corr-1", func() {})
+
+	tc.inFlightMtx.Lock()
+	_, ok := tc.inFlight["corr-1"]
+	tc.inFlightMtx.Unlock()
+	assert.True(t, ok, "request should still be in flight before done() is called")
+
+	tc.completeInFlight(tradeWireMessage{CorrelationID: "corr-1", Success: true})
+	msg := <-respC
+	assert.True(t, msg.Success)
+
+	done()
+
+	tc.inFlightMtx.Lock()
+	_, ok = tc.inFlight["corr-1"]
+	tc.inFlightMtx.Unlock()
+	assert.False(t, ok, "request should be dropped once the caller is done with it")
+}
+
+// TestResendInFlightResendsEveryPendingRequest is the other half of the same
+// fix: once a request is actually tracked across the wait, a reconnect must
+// resend every one of them.
+func TestResendInFlightResendsEveryPendingRequest(t *testing.T) {
+	tc := newTestTradeClient(t)
+
+	var resent []string
+	_, done1 := tc.trackInFlight("corr-1", func() { resent = append(resent, "corr-1") })
+	_, done2 := tc.trackInFlight("corr-2", func() { resent = append(resent, "corr-2") })
+	defer done1()
+	defer done2()
+
+	tc.resendInFlight(ConnStateAuthenticating, ConnStateAuthenticated)
+
+	assert.ElementsMatch(t, []string{"corr-1", "corr-2"}, resent)
+}
+
+func TestDispatchMessageAuthResultTransitionsToAuthenticatedAndFiresReady(t *testing.T) {
+	tc := newTestTradeClient(t)
+
+	ready := make(chan struct{}, 1)
+	tc.OnReady(func() { ready <- struct{}{} })
+
+	data, err := json.Marshal(tradeWireMessage{Type: "authResult", Success: true})
+	assert.NoError(t, err)
+
+	tc.dispatchMessage(1, data)
+
+	assert.Equal(t, ConnStateAuthenticated, tc.getState())
+	<-ready
+}
+
+func TestDispatchMessageAuthResultFailureReportsErrorWithoutAuthenticating(t *testing.T) {
+	tc := newTestTradeClient(t)
+
+	errC := make(chan error, 1)
+	tc.OnError(func(marketID common.MarketID, err error, disconnecting bool) {
+		errC <- err
+	})
+
+	data, err := json.Marshal(tradeWireMessage{Type: "authResult", Success: false, Error: "bad key"})
+	assert.NoError(t, err)
+
+	tc.dispatchMessage(1, data)
+
+	assert.NotEqual(t, ConnStateAuthenticated, tc.getState())
+	assert.EqualError(t, <-errC, "bad key")
+}
+
+func TestDispatchMessageSubscriptionResultFiresListeners(t *testing.T) {
+	tc := newTestTradeClient(t)
+
+	gotC := make(chan SubscriptionResult, 1)
+	tc.OnSubscriptionResult(func(sr SubscriptionResult) {
+		gotC <- sr
+	})
+
+	data, err := json.Marshal(tradeWireMessage{
+		Type:               "subscriptionResult",
+		SubscriptionResult: &SubscriptionResult{Subscribed: []string{"a"}},
+	})
+	assert.NoError(t, err)
+
+	tc.dispatchMessage(1, data)
+
+	got := <-gotC
+	assert.Equal(t, []string{"a"}, got.Subscribed)
+}
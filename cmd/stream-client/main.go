@@ -0,0 +1,100 @@
+/*
+This is a simple app that demonstrates streaming a consistent order book for
+a market using the SDK's StreamConn and OrderBookSubscription.
+*/
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/khcchiu/cw-sdk-go/client/websocket"
+	"github.com/khcchiu/cw-sdk-go/common"
+	"github.com/khcchiu/cw-sdk-go/config"
+
+	flag "github.com/spf13/pflag"
+)
+
+var subs []string
+
+func main() {
+	var configPath string
+	var marketID int
+
+	flag.StringVarP(&configPath, "config", "c", "", "Configuration file")
+	flag.StringSliceVar(&subs, "sub", nil, "Subscription key. This flag can be given multiple times")
+	flag.IntVar(&marketID, "marketid", 1, "Market to stream order book updates for")
+
+	flag.Parse()
+
+	var (
+		cfg    *config.CWConfig
+		cfgErr error
+	)
+
+	if configPath != "" {
+		cfg, cfgErr = config.NewFromPath(configPath)
+		if cfgErr != nil {
+			log.Print(cfgErr)
+			os.Exit(1)
+		}
+	} else {
+		cfg = config.Get()
+	}
+
+	// Market data is public: unlike TradeClient, StreamConn doesn't need
+	// cfg.APIKey/SecretKey.
+	sc, err := websocket.NewStreamConn(&websocket.StreamClientParams{
+		WSParams: &websocket.WSParams{
+			URL: cfg.StreamURL,
+		},
+		Subscriptions: subs,
+	})
+	if err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+
+	// Will print state changes to the user.
+	sc.OnStateChange(
+		websocket.ConnStateAny,
+		func(oldState, state websocket.ConnState) {
+			log.Printf("State updated: %s -> %s", websocket.ConnStateNames[oldState], websocket.ConnStateNames[state])
+		},
+	)
+
+	mID := common.MarketID(marketID)
+
+	obs := websocket.NewOrderBookSubscription(sc, nil)
+
+	obs.OnBookUpdate(mID, func(book common.Book, diff common.Diff) {
+		log.Printf("Book update: market=%d bids=%d asks=%d seq=%d", book.MarketID, len(book.Bids), len(book.Asks), book.SeqNum)
+	})
+
+	obs.OnResync(func(marketID common.MarketID) {
+		log.Printf("Resyncing order book for market %d", marketID)
+	})
+
+	if err := obs.Subscribe(mID); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+
+	// Setup OS signal handler.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	log.Printf("Connecting to %s ...\n", cfg.StreamURL)
+	sc.Connect()
+
+	// Wait until the OS signal is received, at which point we'll close the
+	// connection and quit.
+	<-interrupt
+	log.Println("Closing connection...")
+
+	if err := sc.Close(); err != nil {
+		log.Printf("Failed to close connection: %s", err)
+	}
+}
@@ -0,0 +1,11 @@
+package common
+
+// Balance is the available amount of a single currency in an exchange
+// account.
+type Balance struct {
+	Currency string
+	Amount   string
+}
+
+// Balances maps exchange name to the balances held there.
+type Balances map[string][]Balance
@@ -0,0 +1,11 @@
+package common
+
+// MarketID identifies a market (a trading pair on a particular exchange) as
+// known to the Cryptowatch backend.
+type MarketID int64
+
+// MarketParams identifies a market that a client wants to subscribe or trade
+// on.
+type MarketParams struct {
+	ID MarketID
+}
@@ -0,0 +1,36 @@
+package common
+
+import "strings"
+
+// MultiError aggregates the errors from a batch operation (e.g.
+// TradeClient.CancelAllOrders fanning out individual cancellations) into a
+// single error, while preserving each one.
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// NewMultiError returns a *MultiError built from errs, dropping any nil
+// entries. It returns nil if nothing remains, so it's safe to return
+// directly from a function's error result.
+func NewMultiError(errs []error) error {
+	var filtered []error
+	for _, err := range errs {
+		if err != nil {
+			filtered = append(filtered, err)
+		}
+	}
+
+	if len(filtered) == 0 {
+		return nil
+	}
+
+	return &MultiError{Errors: filtered}
+}
@@ -0,0 +1,144 @@
+package common
+
+import "time"
+
+// OrderSide indicates whether an order buys or sells the base currency of a
+// market.
+type OrderSide int
+
+const (
+	OrderSideUnknown OrderSide = iota
+	OrderSideBuy
+	OrderSideSell
+)
+
+// OrderType is the kind of order being placed.
+type OrderType int
+
+const (
+	OrderTypeUnknown OrderType = iota
+	LimitOrder
+	MarketOrder
+	StopLimit
+	StopMarket
+	TakeProfitLimit
+	TrailingStop
+)
+
+// MarginMode selects how margin is allocated for a leveraged order.
+type MarginMode int
+
+const (
+	MarginModeUnknown MarginMode = iota
+	MarginModeCross
+	MarginModeIsolated
+)
+
+// TimeInForce controls how long an order rests before it's canceled.
+type TimeInForce int
+
+const (
+	TimeInForceUnknown TimeInForce = iota
+
+	// GTC: good 'til canceled.
+	GTC
+
+	// IOC: immediate or cancel -- fill what can be filled right away and
+	// cancel the rest.
+	IOC
+
+	// FOK: fill or kill -- fill the whole order right away, or cancel it.
+	FOK
+
+	// GTD: good 'til date -- like GTC, but expires at
+	// PlaceOrderParams.TimeInForceExpiry.
+	GTD
+)
+
+// TriggerType selects what kind of conditional order TriggerPrice arms.
+type TriggerType int
+
+const (
+	TriggerTypeUnknown TriggerType = iota
+	TriggerTypeStopLoss
+	TriggerTypeTakeProfit
+)
+
+// PriceParamType indicates how a PriceParam's Value should be interpreted.
+type PriceParamType int
+
+const (
+	PriceParamTypeUnknown PriceParamType = iota
+
+	// AbsoluteValuePrice means Value is an absolute price.
+	AbsoluteValuePrice
+
+	// RelativeValuePrice means Value is relative to the last trade price.
+	RelativeValuePrice
+)
+
+// PriceParam is one component of an order's price; orders can have several
+// of these stacked (e.g. a base price plus a relative offset).
+type PriceParam struct {
+	Value string
+	Type  PriceParamType
+}
+
+// PlaceOrderParams describes an order to be placed via TradeClient.PlaceOrder.
+type PlaceOrderParams struct {
+	PriceParams []*PriceParam
+	MarketID    MarketID
+	Amount      string
+	OrderSide   OrderSide
+	OrderType   OrderType
+
+	// ClientOrderID, if set, lets the caller safely retry a placement
+	// without risking a duplicate order: the exchange treats repeated
+	// placements with the same ClientOrderID as idempotent.
+	ClientOrderID string
+
+	// Leverage and MarginMode apply to margin/futures orders; Leverage is
+	// the exchange-specific multiplier (e.g. 1 for spot, unleveraged).
+	Leverage   int
+	MarginMode MarginMode
+
+	// ReduceOnly requires the order to only reduce an existing position,
+	// never open or flip one. PostOnly requires it to only add liquidity,
+	// rejecting (or requeuing) if it would otherwise take immediately.
+	ReduceOnly bool
+	PostOnly   bool
+
+	// TimeInForce defaults to GTC if left unset. TimeInForceExpiry is only
+	// consulted when TimeInForce is GTD.
+	TimeInForce       TimeInForce
+	TimeInForceExpiry time.Time
+
+	// TriggerPrice and TriggerType arm a conditional order (StopLimit,
+	// StopMarket, TakeProfitLimit, TrailingStop); they're ignored for plain
+	// LimitOrder/MarketOrder.
+	TriggerPrice string
+	TriggerType  TriggerType
+}
+
+// CancelOrderParams identifies an order to be canceled via
+// TradeClient.CancelOrder.
+type CancelOrderParams struct {
+	MarketID MarketID
+	OrderID  string
+}
+
+// Order is a resting or historical order as reported by the exchange.
+type Order struct {
+	ID          string
+	MarketID    MarketID
+	PriceParams []*PriceParam
+	Amount      string
+	OrderSide   OrderSide
+	OrderType   OrderType
+}
+
+// PlacedOrder is returned by TradeClient.PlaceOrder once the exchange has
+// acknowledged the order.
+type PlacedOrder struct {
+	Order
+}
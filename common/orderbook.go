@@ -0,0 +1,32 @@
+package common
+
+// PriceLevel is one price/amount pair in an order book. An Amount of "0"
+// means the level should be removed.
+type PriceLevel struct {
+	Price  string
+	Amount string
+}
+
+// Book is a consistent, gap-free snapshot of a market's L2 order book at
+// SeqNum.
+type Book struct {
+	MarketID MarketID
+	Bids     []PriceLevel
+	Asks     []PriceLevel
+
+	// SeqNum is the sequence number of the last update applied to this
+	// book; it lines up with Diff.LastID.
+	SeqNum uint64
+}
+
+// Diff is an incremental order book update. FirstID and LastID bound the
+// range of sequence numbers this diff covers, so a subscriber can detect
+// gaps (FirstID > previous LastID+1) and out-of-order/duplicate delivery.
+type Diff struct {
+	MarketID MarketID
+	Bids     []PriceLevel
+	Asks     []PriceLevel
+
+	FirstID uint64
+	LastID  uint64
+}
@@ -0,0 +1,49 @@
+// Package config loads Cryptowatch credentials and endpoints, either from a
+// config file or from the environment, for use by the cw-sdk-go clients.
+package config
+
+import (
+	"io/ioutil"
+
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultTradeURL  = "wss://trading.service.cryptowat.ch"
+	defaultStreamURL = "wss://stream.cryptowat.ch"
+)
+
+// CWConfig holds the credentials and endpoints needed to talk to the
+// Cryptowatch backend. APIKey/SecretKey authenticate a TradeClient session;
+// StreamConn market data is public and doesn't use them.
+type CWConfig struct {
+	APIKey    string `yaml:"apikey"`
+	SecretKey string `yaml:"secretkey"`
+	TradeURL  string `yaml:"tradeurl"`
+	StreamURL string `yaml:"streamurl"`
+}
+
+// Get returns a CWConfig populated with defaults. Callers that need
+// credentials from a file should use NewFromPath instead.
+func Get() *CWConfig {
+	return &CWConfig{
+		TradeURL:  defaultTradeURL,
+		StreamURL: defaultStreamURL,
+	}
+}
+
+// NewFromPath reads a CWConfig from the YAML file at path.
+func NewFromPath(path string) (*CWConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Annotatef(err, "reading config from %s", path)
+	}
+
+	cfg := Get()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, errors.Annotatef(err, "parsing config from %s", path)
+	}
+
+	return cfg, nil
+}